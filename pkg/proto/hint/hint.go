@@ -18,8 +18,6 @@
 package hint
 
 import (
-	"bufio"
-	"bytes"
 	"strings"
 )
 
@@ -31,37 +29,33 @@ import (
 	"github.com/arana-db/arana/pkg/runtime/misc"
 )
 
+// Built-in hint names, kept as typed constants so existing call sites can
+// keep writing hint.TypeFullScan etc. Each one is registered against the
+// hint registry in registry.go; there is nothing special about a built-in
+// beyond having been registered from this package's own init().
 const (
-	_            Type = iota
-	TypeMaster        // force route to master node
-	TypeSlave         // force route to slave node
-	TypeRoute         // custom route
-	TypeFullScan      // enable full-scan
-	TypeDirect        // direct route
-	TypeTrace         // distributed tracing
+	TypeMaster   Type = "MASTER"
+	TypeSlave    Type = "SLAVE"
+	TypeRoute    Type = "ROUTE"
+	TypeFullScan Type = "FULLSCAN"
+	TypeDirect   Type = "DIRECT"
+	TypeTrace    Type = "TRACE"
 )
 
-var _hintTypes = [...]string{
-	TypeMaster:   "MASTER",
-	TypeSlave:    "SLAVE",
-	TypeRoute:    "ROUTE",
-	TypeFullScan: "FULLSCAN",
-	TypeDirect:   "DIRECT",
-	TypeTrace:    "TRACE",
-}
-
 // KeyValue represents a pair of key and value.
 type KeyValue struct {
-	K string // key (optional)
-	V string // value
+	K      string // key (optional)
+	V      string // value
+	Quoted bool   // whether V was written as a quoted string literal
 }
 
-// Type represents the type of Hint.
-type Type uint8
+// Type identifies a hint by its canonical (upper-cased) name, as looked up
+// in the hint registry.
+type Type string
 
 // String returns the display string.
 func (tp Type) String() string {
-	return _hintTypes[tp]
+	return string(tp)
 }
 
 // Hint represents a Hint, a valid Hint should include type and input kv pairs.
@@ -70,9 +64,15 @@ func (tp Type) String() string {
 //   - without inputs: YOUR_HINT()
 //   - with non-keyed inputs: YOUR_HINT(foo,bar,quz)
 //   - with keyed inputs: YOUR_HINT(x=foo,y=bar,z=quz)
+//   - with quoted inputs, which may contain ',', '=' or whitespace:
+//     YOUR_HINT(sql="select a,b from t where c=1")
 type Hint struct {
 	Type   Type
 	Inputs []KeyValue
+	// Payload is the typed value built by the hint's Descriptor.New, if
+	// the registered descriptor provides one. It is nil for hints that
+	// don't need anything beyond their raw Inputs.
+	Payload interface{}
 }
 
 // String returns the display string.
@@ -92,7 +92,17 @@ func (h Hint) String() string {
 			sb.WriteString(key)
 			sb.WriteByte('=')
 		}
-		sb.WriteString(p.V)
+		if p.Quoted {
+			sb.WriteByte('"')
+			// Escape backslashes before quotes: escaping in the other order
+			// would double the backslashes writeKv itself just inserted.
+			escaped := strings.ReplaceAll(p.V, `\`, `\\`)
+			escaped = strings.ReplaceAll(escaped, `"`, `\"`)
+			sb.WriteString(escaped)
+			sb.WriteByte('"')
+		} else {
+			sb.WriteString(p.V)
+		}
 	}
 
 	writeKv(h.Inputs[0])
@@ -105,12 +115,14 @@ func (h Hint) String() string {
 	return sb.String()
 }
 
-// Parse parses Hint from an input string.
+// Parse parses Hint from an input string. The hint's name is looked up in
+// the registry (see Register); an unregistered name, a schema violation
+// (unknown/missing key, failed Validate) or malformed syntax are all
+// reported as an error instead of silently producing a best-effort guess.
+// Blank inputs (an empty token, or a k=v token whose key or unquoted value
+// is blank) are omitted rather than reported.
 func Parse(s string) (*Hint, error) {
-	var (
-		tpStr string
-		tp    Type
-	)
+	var tpStr string
 
 	offset := strings.Index(s, "(")
 	if offset == -1 {
@@ -119,75 +131,178 @@ func Parse(s string) (*Hint, error) {
 		tpStr = s[:offset] //开头到(之前的内容
 	}
 
-	for i, v := range _hintTypes {
-		if strings.EqualFold(tpStr, v) { //判断两个utf-8编码字符串（将unicode大写、小写、标题三种格式字符视为相同）是否相同
-			tp = Type(i)
-			break
-		}
-	}
-
-	if tp == 0 {
-		return nil, errors.Errorf("hint: invalid input '%s'", s)
+	desc, ok := lookup(tpStr)
+	if !ok {
+		return nil, errors.Errorf("hint: unknown hint '%s'", tpStr)
 	}
+	tp := Type(strings.ToUpper(desc.Name))
 
 	if offset == -1 {
-		return &Hint{Type: tp}, nil
+		return newHint(tp, desc, nil)
 	}
 
 	end := strings.LastIndex(s, ")")
-	if end == -1 {
+	if end == -1 || end <= offset || !misc.IsBlank(s[end+1:]) {
 		return nil, errors.Errorf("hint: invalid input '%s'", s)
 	}
 
 	s = s[offset+1 : end] //括号里面的内容
 
-	scanner := bufio.NewScanner(strings.NewReader(s)) //为什么不直接用strings.Split?
-	scanner.Split(scanComma)
+	rawTokens, err := splitUnquoted(s, ',', 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "hint: invalid input '%s'", s)
+	}
 
 	var kvs []KeyValue
+	for _, raw := range rawTokens {
+		kv, ok, err := parseToken(raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "hint: invalid input '%s'", s)
+		}
+		if ok {
+			kvs = append(kvs, kv)
+		}
+	}
 
-	for scanner.Scan() {
-		text := scanner.Text() //scan与text配合，按split分割拿到每个元素，直到结束
+	return newHint(tp, desc, kvs)
+}
 
-		// split kv by '='
-		i := strings.Index(text, "=")
-		if i == -1 {
-			// omit blank text
-			if misc.IsBlank(text) {
-				continue
-			}
-			kvs = append(kvs, KeyValue{V: strings.TrimSpace(text)}) //没有任何=，那么k=空，v=去掉空格后text
-		} else {
-			var (
-				k = strings.TrimSpace(text[:i])
-				v = strings.TrimSpace(text[i+1:])
-			)
-			// omit blank key/value
-			if misc.IsBlank(k) || misc.IsBlank(v) {
-				continue
-			}
-			kvs = append(kvs, KeyValue{K: k, V: v}) //按照=拆分kv
+// newHint validates kvs against desc's schema and, if the descriptor
+// provides one, builds the typed Payload.
+func newHint(tp Type, desc *Descriptor, kvs []KeyValue) (*Hint, error) {
+	if err := desc.validate(kvs); err != nil {
+		return nil, errors.Wrapf(err, "hint: %s", desc.Name)
+	}
+
+	h := &Hint{Type: tp, Inputs: kvs}
+
+	if desc.New != nil {
+		payload, err := desc.New(kvs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "hint: %s", desc.Name)
 		}
+		h.Payload = payload
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, errors.Wrapf(err, "hint: invalid input '%s'", s)
+	return h, nil
+}
+
+// parseToken parses a single (possibly "k=v") token already split off at a
+// top-level comma. It reports ok=false for blank tokens, and for k=v tokens
+// whose key or unquoted value is blank, which callers should omit just like
+// the legacy scanner did.
+func parseToken(raw string) (kv KeyValue, ok bool, err error) {
+	raw = strings.TrimSpace(raw)
+	if misc.IsBlank(raw) {
+		return KeyValue{}, false, nil
+	}
+
+	parts, err := splitUnquoted(raw, '=', 2)
+	if err != nil {
+		return KeyValue{}, false, err
 	}
 
-	return &Hint{Type: tp, Inputs: kvs}, nil
+	if len(parts) == 1 {
+		v, quoted, err := unquote(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return KeyValue{}, false, err
+		}
+		if !quoted && misc.IsBlank(v) {
+			return KeyValue{}, false, nil
+		}
+		return KeyValue{V: v, Quoted: quoted}, true, nil
+	}
+
+	k := strings.TrimSpace(parts[0])
+	v, quoted, err := unquote(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return KeyValue{}, false, err
+	}
+	if misc.IsBlank(k) || (!quoted && misc.IsBlank(v)) {
+		return KeyValue{}, false, nil
+	}
+	return KeyValue{K: k, V: v, Quoted: quoted}, true, nil
 }
 
-func scanComma(data []byte, atEOF bool) (advance int, token []byte, err error) {
-	if atEOF && len(data) == 0 {
-		return 0, nil, nil
+// unquote strips a surrounding pair of single or double quotes from s and
+// resolves backslash escapes within it, reporting whether s was quoted at
+// all. Unquoted input is returned verbatim.
+func unquote(s string) (value string, quoted bool, err error) {
+	if len(s) < 2 {
+		return s, false, nil
+	}
+
+	q := s[0]
+	if (q != '\'' && q != '"') || s[len(s)-1] != q {
+		return s, false, nil
 	}
-	if i := bytes.IndexByte(data, ','); i >= 0 {
-		return i + 1, data[0:i], nil
+
+	return unescape(s[1 : len(s)-1]), true, nil
+}
+
+// unescape resolves backslash escapes (\x -> x) in a quoted string's inner
+// content.
+func unescape(s string) string {
+	if !strings.ContainsRune(s, '\\') {
+		return s
 	}
-	if atEOF {
-		return len(data), data, nil
+
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) {
+			i++
+			c = s[i]
+		}
+		sb.WriteByte(c)
 	}
-	return 0, nil, nil
+	return sb.String()
+}
+
+// splitUnquoted splits s on sep, skipping any sep that appears inside a
+// single- or double-quoted span so that quoted values may safely contain
+// the separator (e.g. a comma inside a quoted SQL snippet). A backslash
+// escapes the following character anywhere inside a quoted span, including
+// the quote character itself. When limit > 0, at most limit pieces are
+// returned, mirroring strings.SplitN's semantics for the trailing piece.
+// An unterminated quote is reported as an error rather than silently
+// dropped, so malformed hints fail fast instead of parsing into garbage.
+func splitUnquoted(s string, sep byte, limit int) ([]string, error) {
+	var (
+		parts []string
+		start int
+		quote byte
+	)
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			switch c {
+			case '\\':
+				if i+1 < len(s) {
+					i++
+				}
+			case quote:
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == sep:
+			if limit <= 0 || len(parts)+1 < limit {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.Errorf("unterminated quote in '%s'", s)
+	}
+
+	parts = append(parts, s[start:])
+	return parts, nil
 }
 
 func Contains(hType Type, hints []*Hint) bool {
@@ -0,0 +1,112 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hint
+
+import (
+	"testing"
+)
+
+// FuzzParse exercises the hint tokenizer (splitUnquoted/unquote/parseToken)
+// via Parse. It must never panic, and whatever error it returns must be a
+// plain error, not a crash, on malformed input such as nested quotes,
+// trailing commas or unbalanced parentheses.
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		`ROUTE()`,
+		`ROUTE(db0,tb0)`,
+		`DIRECT(db=db0,tb=tb0)`,
+		`ROUTE(sql="select a,b from t where c=1")`,
+		// nested quotes
+		`ROUTE(sql="select '1' as a")`,
+		`ROUTE(sql='select "1" as a')`,
+		`ROUTE(sql="a \"b\" c")`,
+		// trailing commas
+		`ROUTE(db0,tb0,)`,
+		`DIRECT(db=db0,tb=tb0,)`,
+		`ROUTE(,)`,
+		// unbalanced parentheses
+		`ROUTE(db0`,
+		`ROUTE(db0))`,
+		`ROUTE((db0)`,
+		`TRACE(sampler="a(b"`,
+		// unterminated quotes
+		`ROUTE(sql="select 1)`,
+		`ROUTE(sql='select 1)`,
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("Parse(%q) panicked: %v", s, r)
+			}
+		}()
+
+		h, err := Parse(s)
+		if err != nil {
+			return
+		}
+		// A successfully parsed hint must re-tokenize to the same number of
+		// inputs when its String() form is fed back in, since String() is
+		// meant to be a faithful re-quoting of Inputs (see hint.go).
+		h2, err := Parse(h.String())
+		if err != nil {
+			t.Fatalf("Parse(%q) succeeded but re-parsing its String() form %q failed: %v", s, h.String(), err)
+		}
+		if len(h2.Inputs) != len(h.Inputs) {
+			t.Fatalf("round-trip through String() changed input count: %q -> %q", s, h.String())
+		}
+		for i := range h.Inputs {
+			if h.Inputs[i].V != h2.Inputs[i].V {
+				t.Fatalf("round-trip through String() changed value %d: %q -> %q (%q vs %q)", i, s, h.String(), h.Inputs[i].V, h2.Inputs[i].V)
+			}
+		}
+	})
+}
+
+// FuzzSplitUnquoted targets splitUnquoted directly with inputs designed to
+// probe nested quotes, trailing separators and unbalanced/unterminated
+// quoting, since it is the lowest-level piece of the tokenizer.
+func FuzzSplitUnquoted(f *testing.F) {
+	seeds := []string{
+		``,
+		`a,b,c`,
+		`a,b,`,
+		`,a,b`,
+		`"a,b",c`,
+		`'a,b',"c,d"`,
+		`"a\"b",c`,
+		`"a`,
+		`'a"b'`,
+		`(((`,
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("splitUnquoted(%q) panicked: %v", s, r)
+			}
+		}()
+		_, _ = splitUnquoted(s, ',', 0)
+	})
+}
@@ -0,0 +1,213 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hint
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+)
+
+// Handler applies a parsed hint instance's runtime effect by decorating
+// plan, for hints whose entire effect is expressible as "wrap the plan the
+// optimizer already built" (e.g. TRACE wrapping ExecIn with a root span).
+//
+// Hints that instead change routing/sharding decisions specific to one
+// statement type (FULLSCAN, DIRECT, ROUTE) aren't a plan decoration at all
+// — they need access to that statement's VTable/topology — so they have no
+// Handler and remain the responsibility of that statement's optimizeXxx
+// callback, which already has the context a generic decorator wouldn't.
+type Handler func(ctx context.Context, plan proto.Plan, h *Hint) (proto.Plan, error)
+
+// Descriptor describes a registered hint: its accepted shape (which keys it
+// takes, whether it accepts positional values, which keys are mandatory)
+// and, optionally, how to turn its parsed Inputs into a typed payload or
+// apply its runtime effect to a plan.
+//
+// A Descriptor is the unit external packages register via Register to add
+// a domain-specific hint (e.g. TIMEOUT(ms=500)) without editing this
+// package.
+type Descriptor struct {
+	// Name is the hint's keyword, matched case-insensitively, e.g. "ROUTE".
+	Name string
+	// Keyed lists the keys this hint accepts in k=v form. nil means no
+	// restriction beyond Required (the historical default for hints that
+	// predate this field); a non-nil empty slice means this hint accepts
+	// no keyed input at all.
+	Keyed []string
+	// Required lists the subset of Keyed that must be present.
+	Required []string
+	// Positional, when true, accepts bare (non-keyed) values in addition to
+	// Keyed ones, e.g. MASTER() or ROUTE(db0,tb0).
+	Positional bool
+	// Validate, if set, is called once per keyed input with its raw
+	// (already unquoted) value, so malformed hints fail fast with an
+	// actionable error instead of being silently dropped.
+	Validate func(key, value string) error
+	// New builds the typed payload exposed via Hint.Payload from the
+	// parsed Inputs. Optional; hints with no structured payload (most of
+	// the built-ins) leave this nil.
+	New func(inputs []KeyValue) (interface{}, error)
+	// Handler, if set, is invoked by ApplyHandlers for every parsed
+	// instance of this hint, letting it decorate the optimizer's plan
+	// generically instead of every optimizeXxx callback special-casing
+	// this hint's Type. Installed via SetHandler rather than inline at
+	// Register time: for TRACE, the implementation lives in the optimize
+	// package, which already imports this one, so wiring it the other way
+	// round here would be a cycle.
+	Handler Handler
+}
+
+// validate checks kvs against d's schema: unknown keys, missing required
+// keys, disallowed positional values and per-key Validate failures are all
+// reported here so Parse can fail fast with one actionable error.
+func (d *Descriptor) validate(kvs []KeyValue) error {
+	seen := make(map[string]bool, len(kvs))
+
+	for _, kv := range kvs {
+		if len(kv.K) == 0 {
+			if !d.Positional {
+				return errors.Errorf("positional input '%s' is not allowed, expected key=value", kv.V)
+			}
+			continue
+		}
+
+		if d.Keyed != nil && !containsFold(d.Keyed, kv.K) {
+			if len(d.Keyed) == 0 {
+				return errors.Errorf("'%s' accepts no keyed inputs, got '%s'", d.Name, kv.K)
+			}
+			return errors.Errorf("unknown key '%s', expected one of %v", kv.K, d.Keyed)
+		}
+
+		if d.Validate != nil {
+			if err := d.Validate(kv.K, kv.V); err != nil {
+				return errors.Wrapf(err, "invalid value for '%s'", kv.K)
+			}
+		}
+
+		seen[strings.ToUpper(kv.K)] = true
+	}
+
+	for _, req := range d.Required {
+		if !seen[strings.ToUpper(req)] {
+			return errors.Errorf("missing required key '%s'", req)
+		}
+	}
+
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	_registryMu sync.RWMutex
+	_registry   = map[string]*Descriptor{}
+)
+
+// Register adds d to the hint registry so that Parse recognizes its name.
+// It panics on a nil/unnamed descriptor or on re-registering an existing
+// name, the same fail-fast convention used elsewhere for this kind of
+// global, init()-time registration (e.g. optimize.Register) — a silently
+// overwritten hint would be far harder to debug than a boot-time panic.
+func Register(d *Descriptor) {
+	if d == nil || len(d.Name) == 0 {
+		panic("hint: descriptor must have a name")
+	}
+
+	key := strings.ToUpper(d.Name)
+
+	_registryMu.Lock()
+	defer _registryMu.Unlock()
+
+	if _, ok := _registry[key]; ok {
+		panic("hint: duplicate hint registration for " + d.Name)
+	}
+	_registry[key] = d
+}
+
+// SetHandler installs (or replaces) the Handler on an already-registered
+// hint's Descriptor. It is a no-op if the hint type isn't registered.
+//
+// This exists so a package that implements a hint's runtime effect (e.g.
+// optimize, for TRACE) can wire itself in from its own init() without this
+// package needing to import it back: Register happens here, at this
+// package's own init() time, while Handler wiring happens later, from
+// whichever package actually knows how to decorate a proto.Plan.
+func SetHandler(t Type, handler Handler) {
+	_registryMu.Lock()
+	defer _registryMu.Unlock()
+	if d, ok := _registry[strings.ToUpper(string(t))]; ok {
+		d.Handler = handler
+	}
+}
+
+// ApplyHandlers runs every hint in hints whose registered Descriptor
+// provides a Handler, decorating plan in hint order. Hints with no Handler
+// (including any unregistered Type, which shouldn't happen past Parse) are
+// skipped.
+func ApplyHandlers(ctx context.Context, plan proto.Plan, hints []*Hint) (proto.Plan, error) {
+	for _, h := range hints {
+		desc, ok := lookup(string(h.Type))
+		if !ok || desc.Handler == nil {
+			continue
+		}
+
+		var err error
+		if plan, err = desc.Handler(ctx, plan, h); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+func lookup(name string) (*Descriptor, bool) {
+	_registryMu.RLock()
+	defer _registryMu.RUnlock()
+	d, ok := _registry[strings.ToUpper(name)]
+	return d, ok
+}
+
+func init() {
+	Register(&Descriptor{Name: "MASTER", Positional: true})
+	Register(&Descriptor{Name: "SLAVE", Positional: true})
+	Register(&Descriptor{Name: "ROUTE", Keyed: []string{"sql"}, Positional: true})
+	Register(&Descriptor{Name: "FULLSCAN", Positional: true})
+	Register(&Descriptor{Name: "DIRECT", Keyed: []string{"db", "tb"}, Positional: true})
+	// TRACE only toggles root-span creation for now (see the Handler the
+	// optimize package installs via SetHandler); it accepts no keyed
+	// input. Provider selection and sampling are a startup-time
+	// TracerProvider concern (see trace.SetTracerProvider), not something
+	// a per-query hint can reconfigure, so sampler=/exporter=/ratio= are
+	// rejected rather than silently accepted-and-ignored.
+	Register(&Descriptor{Name: "TRACE", Keyed: []string{}})
+}
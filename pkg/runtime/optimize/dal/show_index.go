@@ -19,10 +19,12 @@ package dal
 
 import (
 	"context"
+	"strings"
 )
 
 import (
 	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/hint"
 	"github.com/arana-db/arana/pkg/proto/rule"
 	"github.com/arana-db/arana/pkg/runtime/ast"
 	"github.com/arana-db/arana/pkg/runtime/optimize"
@@ -33,26 +35,54 @@ func init() {
 	optimize.Register(ast.SQLTypeShowIndex, optimizeShowIndex)
 }
 
+// defaultShowIndexFullscan controls whether SHOW INDEX fans out to every
+// shard when the statement carries no explicit FULLSCAN hint. Operators who
+// want divergence detection on by default can flip this, but the safer
+// single-shard behavior remains the out-of-the-box default.
+var defaultShowIndexFullscan = false
+
 // 显示指定表的所有索引
 // 需要最后一个逻辑表的 库名：表名映射
-// 默认取库0：表0
-func optimizeShowIndex(_ context.Context, o *optimize.Optimizer) (proto.Plan, error) {
+// 默认取库0：表0，除非命中 FULLSCAN hint 或开启了 defaultShowIndexFullscan，
+// 此时会遍历所有分片并对结果做一致性合并
+func optimizeShowIndex(ctx context.Context, o *optimize.Optimizer) (proto.Plan, error) {
 	stmt := o.Stmt.(*ast.ShowIndex)
 
-	ret := &dal.ShowIndexPlan{Stmt: stmt}
+	fullscan := defaultShowIndexFullscan || hint.Contains(hint.TypeFullScan, o.Hints)
+
+	ret := &dal.ShowIndexPlan{Stmt: stmt, Fullscan: fullscan, Verbose: fullscan}
 	ret.BindArgs(o.Args)
 
+	// Restored once, up front, so every return path below tags a TRACE()
+	// root span (see optimize.ApplyHints) with the actual statement, not a
+	// bare table name.
+	var sql string
+	var sb strings.Builder
+	if err := stmt.Restore(ast.RestoreDefault, &sb, nil); err == nil {
+		sql = sb.String()
+	}
+
 	vt, ok := o.Rule.VTable(stmt.TableName.Suffix())
 	if !ok {
-		return ret, nil
+		return optimize.ApplyHints(ctx, ret, o.Hints, sql)
 	}
 
 	shards := rule.DatabaseTables{}
 
 	topology := vt.Topology()
-	if d, t, ok := topology.Render(0, 0); ok {
-		shards[d] = append(shards[d], t)
+	if !fullscan {
+		if d, t, ok := topology.Render(0, 0); ok {
+			shards[d] = append(shards[d], t)
+		}
+	} else {
+		topology.Each(func(dbIdx, tbIdx int) bool {
+			if d, t, ok := topology.Render(dbIdx, tbIdx); ok {
+				shards[d] = append(shards[d], t)
+			}
+			return true
+		})
 	}
 	ret.Shards = shards
-	return ret, nil
+
+	return optimize.ApplyHints(ctx, ret, o.Hints, sql)
 }
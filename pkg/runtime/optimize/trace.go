@@ -0,0 +1,81 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package optimize
+
+import (
+	"context"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/hint"
+	"github.com/arana-db/arana/pkg/runtime/trace"
+)
+
+func init() {
+	hint.SetHandler(hint.TypeTrace, traceHandler)
+}
+
+// sqlCtxKey is the context key ApplyHints uses to hand a statement's
+// restored SQL text to whichever hint.Handler wants it (currently just
+// traceHandler's), without widening hint.Handler's signature to carry every
+// hint's own auxiliary data.
+type sqlCtxKey struct{}
+
+// ApplyHints is the shared seam every optimizeXxx callback should run its
+// plan through, instead of each one wiring a specific hint's side effect by
+// hand: it hands sql to hint.ApplyHandlers via ctx and lets every hint's
+// registered Handler (if any) decorate plan in turn. A statement type that
+// never calls this simply gets no hint-driven plan decoration — e.g.
+// TRACE() is a no-op for it — the same as before this existed, but any
+// optimizeXxx that does call it picks up every Handler-backed hint for
+// free, not just TRACE.
+func ApplyHints(ctx context.Context, plan proto.Plan, hints []*hint.Hint, sql string) (proto.Plan, error) {
+	return hint.ApplyHandlers(context.WithValue(ctx, sqlCtxKey{}, sql), plan, hints)
+}
+
+// traceHandler is TRACE's hint.Handler: it decorates plan so its ExecIn
+// opens a root span (tagged with the statement's SQL) around the wrapped
+// plan's own ExecIn call and ends it when that returns, so every downstream
+// span the plan itself starts (see pkg/runtime/trace) parents under it.
+//
+// The span is deliberately opened and closed inside ExecIn rather than
+// here: ApplyHints only returns a Plan, it doesn't get to see (or replace)
+// the context.Context the caller later executes that plan with, so
+// stashing a pre-started span anywhere outside of that ExecIn call would
+// either be lost or require threading a ctx field through the plan struct
+// itself.
+func traceHandler(ctx context.Context, plan proto.Plan, _ *hint.Hint) (proto.Plan, error) {
+	sql, _ := ctx.Value(sqlCtxKey{}).(string)
+	return &tracedPlan{Plan: plan, sql: sql}, nil
+}
+
+// tracedPlan decorates a proto.Plan with a root span for its ExecIn call.
+type tracedPlan struct {
+	proto.Plan
+	sql string
+}
+
+// ExecIn starts the root span, parents it onto any W3C trace-context found
+// in SQL comments, and ends it once the wrapped plan's ExecIn returns.
+func (p *tracedPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	ctx = trace.ExtractFromComment(ctx, p.sql)
+	ctx, span := trace.StartRootSpan(ctx, p.sql)
+	defer span.End()
+	return p.Plan.ExecIn(ctx, conn)
+}
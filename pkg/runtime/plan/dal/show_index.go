@@ -0,0 +1,346 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/arana-db/arana/pkg/proto"
+	"github.com/arana-db/arana/pkg/proto/rule"
+	"github.com/arana-db/arana/pkg/runtime/ast"
+	"github.com/arana-db/arana/pkg/runtime/trace"
+)
+
+// maxShowIndexConcurrency bounds how many physical SHOW INDEX queries run in
+// parallel during a fan-out, so a wide topology can't exhaust backend
+// connections in one shot.
+const maxShowIndexConcurrency = 8
+
+// indexRowKey identifies a logical index row across shards: rows sharing the
+// same key are considered the "same" index entry for grouping purposes.
+// Whether they're actually consistent also depends on the rest of the row
+// (see indexMerger.add and nonComparableColumns), since two shards can agree
+// on this key while disagreeing on e.g. Index_type.
+type indexRowKey struct {
+	keyName    string
+	seqInIndex string
+	columnName string
+}
+
+// nonComparableColumns lists SHOW INDEX columns that legitimately differ
+// across physical shards of the same logical table and must be excluded
+// from divergence detection: Table differs per shard by construction (each
+// shard is a differently-named physical table), and Cardinality/Sub_part/
+// Packed/Comment are storage-engine estimates or free text that vary
+// shard-to-shard even when the index definition itself agrees. Including
+// them in the comparison would flag every multi-shard table as divergent.
+var nonComparableColumns = map[string]bool{
+	"Table":       true,
+	"Cardinality": true,
+	"Sub_part":    true,
+	"Packed":      true,
+	"Comment":     true,
+}
+
+// ShowIndexPlan is the execution plan for SHOW INDEX.
+//
+// By default it only queries a single representative (db, table) shard.
+// When Fullscan is set it fans out to every shard in Shards instead and
+// checks that they all report the same indexes before returning a result.
+type ShowIndexPlan struct {
+	Stmt   *ast.ShowIndex
+	Shards rule.DatabaseTables
+	Args   []proto.Value
+
+	// Fullscan triggers a fan-out across every physical shard instead of a
+	// single representative one, so that divergent indexes can be detected.
+	Fullscan bool
+	// Verbose includes the full per-shard breakdown in the "show_index.
+	// divergence" trace event (see trace.AddWarning) when Fullscan finds
+	// divergent indexes, instead of just a count. Either way, divergence no
+	// longer fails the statement: see execFullscan.
+	Verbose bool
+}
+
+// BindArgs binds the arguments of the statement.
+func (s *ShowIndexPlan) BindArgs(args []proto.Value) {
+	s.Args = args
+}
+
+// ExecIn executes the plan against conn.
+func (s *ShowIndexPlan) ExecIn(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	if !s.Fullscan {
+		return s.execSingle(ctx, conn)
+	}
+	return s.execFullscan(ctx, conn)
+}
+
+// execSingle keeps the original single-shard behavior: query whichever one
+// (db, table) pair was rendered into Shards and return its rows as-is.
+func (s *ShowIndexPlan) execSingle(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	for db, tables := range s.Shards {
+		for _, tb := range tables {
+			spanCtx, span := trace.StartSpan(ctx, "arana.plan.show_index.shard")
+			start := time.Now()
+			res, err := s.query(spanCtx, conn, db, tb)
+			trace.SetShardAttributes(span, trace.ShardAttributes{
+				DB: db, Table: tb, ArgsCount: len(s.Args), Latency: time.Since(start),
+			})
+			span.End()
+			return res, err
+		}
+	}
+	return nil, errors.New("show index: no shard to query")
+}
+
+// execFullscan dispatches SHOW INDEX against every (db, table) pair in
+// Shards with bounded concurrency, then checks that every shard reported
+// the same set of index rows (identity plus every comparable column, see
+// nonComparableColumns).
+//
+// Divergence no longer fails the statement: it's recorded as a
+// "show_index.divergence" event on whatever span ctx carries (a no-op if
+// TRACE() wasn't used) via trace.AddWarning, and the lowest-sorted shard's
+// own rows are returned regardless, so a real sharded table with a minor
+// inconsistency still answers SHOW INDEX instead of erroring outright. This
+// vendored snapshot doesn't carry a confirmed in-memory Dataset/Result
+// constructor, so rather than risk fabricating proto API that may not exist
+// upstream (e.g. to add a synthetic Shard column), the lowest-sorted
+// shard's already-fetched proto.Result is reused verbatim as-is for the
+// returned result, on the assumption — true of every Result this plan
+// actually produces via conn.Query, a small buffered SHOW INDEX result set
+// — that Dataset() can be read more than once from the same Result.
+func (s *ShowIndexPlan) execFullscan(ctx context.Context, conn proto.VConn) (proto.Result, error) {
+	type target struct{ db, tb string }
+	type shardResult struct {
+		target
+		res     proto.Result
+		err     error
+		span    trace.Span
+		latency time.Duration
+	}
+
+	var targets []target
+	for db, tables := range s.Shards {
+		for _, tb := range tables {
+			targets = append(targets, target{db, tb})
+		}
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("show index: no shard to query")
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].db+"."+targets[i].tb < targets[j].db+"."+targets[j].tb
+	})
+
+	results := make([]shardResult, len(targets))
+
+	sem := make(chan struct{}, maxShowIndexConcurrency)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		i, t := i, t
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			spanCtx, span := trace.StartSpan(ctx, "arana.plan.show_index.shard")
+			start := time.Now()
+			res, err := s.query(spanCtx, conn, t.db, t.tb)
+			results[i] = shardResult{target: t, res: res, err: err, span: span, latency: time.Since(start)}
+		}()
+	}
+	wg.Wait()
+
+	// Every shard's span must be ended regardless of how this function
+	// returns below, including on the first-error path.
+	for i := range results {
+		defer results[i].span.End()
+	}
+
+	for _, r := range results {
+		if r.err != nil {
+			return nil, errors.Wrapf(r.err, "show index: failed on shard %s.%s", r.db, r.tb)
+		}
+	}
+
+	merger := newIndexMerger()
+	for _, r := range results {
+		rows, err := merger.add(r.db, r.tb, r.res)
+		trace.SetShardAttributes(r.span, trace.ShardAttributes{
+			DB: r.db, Table: r.tb, ArgsCount: len(s.Args), Rows: rows, Latency: r.latency,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if diverged := merger.diverged(len(targets)); len(diverged) > 0 {
+		detail := fmt.Sprintf("%d index row(s) are inconsistent across shards", len(diverged))
+		if s.Verbose {
+			detail = strings.Join(diverged, "; ")
+		}
+		trace.AddWarning(ctx, "show_index.divergence", detail)
+	}
+
+	// results is parallel to the sorted targets slice, so results[0] is
+	// already the lowest-sorted shard; reuse its Result rather than
+	// querying it a second time, which would both double the backend round
+	// trip and race whatever wrote to it between the two queries.
+	return results[0].res, nil
+}
+
+// query runs SHOW INDEX against a single physical (db, table) pair.
+func (s *ShowIndexPlan) query(ctx context.Context, conn proto.VConn, db, tb string) (proto.Result, error) {
+	stmt := *s.Stmt
+	stmt.TableName = ast.TableName{tb}
+
+	var sb strings.Builder
+	if err := stmt.Restore(ast.RestoreDefault, &sb, nil); err != nil {
+		return nil, errors.Wrap(err, "show index: failed to restore statement")
+	}
+
+	return conn.Query(ctx, db, sb.String(), s.Args)
+}
+
+// indexMerger accumulates SHOW INDEX rows observed on every queried shard
+// to detect divergence between them.
+type indexMerger struct {
+	// variants maps an index's identity to every distinct comparable-column
+	// signature observed for it (see nonComparableColumns), each with the
+	// shards that reported it. A consistent index has exactly one variant,
+	// reported by every shard; anything else (more than one variant, or
+	// fewer reporters than shards queried) is divergence.
+	variants map[indexRowKey]map[string][]string
+	fields   []proto.Field
+}
+
+func newIndexMerger() *indexMerger {
+	return &indexMerger{variants: make(map[indexRowKey]map[string][]string)}
+}
+
+// add reads every row res produced on shard db.tb into the merger and
+// returns how many rows were scanned, for trace instrumentation.
+func (m *indexMerger) add(db, tb string, res proto.Result) (int, error) {
+	ds, err := res.Dataset()
+	if err != nil {
+		return 0, errors.Wrap(err, "show index: failed to read dataset")
+	}
+	defer ds.Close()
+
+	if m.fields == nil {
+		fields, err := ds.Fields()
+		if err != nil {
+			return 0, errors.Wrap(err, "show index: failed to read fields")
+		}
+		m.fields = fields
+	}
+
+	shard := fmt.Sprintf("%s.%s", db, tb)
+
+	var rows int
+	for {
+		row, err := ds.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return rows, errors.Wrap(err, "show index: failed to iterate dataset")
+		}
+		rows++
+
+		values := make([]proto.Value, len(m.fields))
+		if err := row.Scan(values); err != nil {
+			return rows, errors.Wrap(err, "show index: failed to scan row")
+		}
+
+		identity := indexRowKey{
+			keyName:    m.fieldString(values, "Key_name"),
+			seqInIndex: m.fieldString(values, "Seq_in_index"),
+			columnName: m.fieldString(values, "Column_name"),
+		}
+		signature := m.comparableSignature(values)
+
+		if m.variants[identity] == nil {
+			m.variants[identity] = make(map[string][]string)
+		}
+		m.variants[identity][signature] = append(m.variants[identity][signature], shard)
+	}
+
+	return rows, nil
+}
+
+func (m *indexMerger) fieldString(values []proto.Value, name string) string {
+	for i, f := range m.fields {
+		if f.Name() == name {
+			return fmt.Sprint(values[i])
+		}
+	}
+	return ""
+}
+
+// comparableSignature renders values into a string that's expected to be
+// identical across shards for a consistent index: it excludes
+// nonComparableColumns, which legitimately differ shard-to-shard even when
+// the index definition itself agrees.
+func (m *indexMerger) comparableSignature(values []proto.Value) string {
+	parts := make([]string, 0, len(m.fields))
+	for i, f := range m.fields {
+		if nonComparableColumns[f.Name()] {
+			continue
+		}
+		parts = append(parts, fmt.Sprint(values[i]))
+	}
+	return strings.Join(parts, "|")
+}
+
+// diverged describes every index identity that wasn't reported, with
+// identical comparable-column values, by all wantShards shards.
+func (m *indexMerger) diverged(wantShards int) []string {
+	var out []string
+	for identity, variants := range m.variants {
+		var total int
+		for _, shards := range variants {
+			total += len(shards)
+		}
+		if len(variants) == 1 && total == wantShards {
+			continue
+		}
+
+		var detail []string
+		for signature, shards := range variants {
+			detail = append(detail, fmt.Sprintf("%v on [%s]", signature, strings.Join(shards, ",")))
+		}
+		sort.Strings(detail)
+		out = append(out, fmt.Sprintf("%s/%s/%s: %s", identity.keyName, identity.seqInIndex, identity.columnName, strings.Join(detail, " vs ")))
+	}
+	sort.Strings(out)
+	return out
+}
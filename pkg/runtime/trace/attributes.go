@@ -0,0 +1,74 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"context"
+	"time"
+)
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys used across arana's spans. Kept as constants so exporters
+// and dashboards can rely on stable names.
+const (
+	keySQL         = attribute.Key("arana.sql")
+	keyDB          = attribute.Key("arana.db")
+	keyTable       = attribute.Key("arana.table")
+	keyArgsCount   = attribute.Key("arana.args_count")
+	keyRowsScanned = attribute.Key("arana.rows_scanned")
+	keyLatencyMs   = attribute.Key("arana.backend_latency_ms")
+	keyWarning     = attribute.Key("arana.warning")
+)
+
+func attrSQL(sql string) attribute.KeyValue {
+	return keySQL.String(sql)
+}
+
+// ShardAttributes describes a single per-shard execution, for annotating
+// the child span StartSpan creates around it.
+type ShardAttributes struct {
+	DB        string
+	Table     string
+	ArgsCount int
+	Rows      int
+	Latency   time.Duration
+}
+
+// SetShardAttributes records sh on span. Call it once the shard execution
+// has finished so Rows and Latency are known.
+func SetShardAttributes(span trace.Span, sh ShardAttributes) {
+	span.SetAttributes(
+		keyDB.String(sh.DB),
+		keyTable.String(sh.Table),
+		keyArgsCount.Int(sh.ArgsCount),
+		keyRowsScanned.Int(sh.Rows),
+		keyLatencyMs.Int64(sh.Latency.Milliseconds()),
+	)
+}
+
+// AddWarning records a non-fatal caveat discovered mid-execution (e.g. a
+// fan-out plan that found inconsistent shards but still returned a
+// best-effort result instead of failing the statement) as an event named
+// name on whatever span ctx carries. It is a no-op if ctx carries no span.
+func AddWarning(ctx context.Context, name, message string) {
+	trace.SpanFromContext(ctx).AddEvent(name, trace.WithAttributes(keyWarning.String(message)))
+}
@@ -0,0 +1,63 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"context"
+	"regexp"
+)
+
+import (
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// sqlCommentKV matches `key='value'` or `key="value"` pairs inside a SQL
+// comment, the convention used by sqlcommenter-style context propagation
+// (e.g. `/* traceparent='00-...-...-01' */`).
+var sqlCommentKV = regexp.MustCompile(`(\w+)\s*=\s*'([^']*)'|(\w+)\s*=\s*"([^"]*)"`)
+
+// sqlBlockComment matches a `/* ... */` comment, whose body is the only
+// place sqlCommentKV is searched.
+var sqlBlockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// ExtractFromComment looks for a W3C trace-context (`traceparent` and
+// optionally `tracestate`) embedded in SQL comments and, if found, returns a
+// context carrying the extracted remote span so arana's own root span (see
+// StartRootSpan) links up with the caller's trace instead of starting a new
+// one.
+func ExtractFromComment(ctx context.Context, sql string) context.Context {
+	carrier := propagation.MapCarrier{}
+	for _, comment := range sqlBlockComment.FindAllString(sql, -1) {
+		for _, m := range sqlCommentKV.FindAllStringSubmatch(comment, -1) {
+			key, value := m[1], m[2]
+			if key == "" {
+				key, value = m[3], m[4]
+			}
+			switch key {
+			case "traceparent", "tracestate":
+				carrier[key] = value
+			}
+		}
+	}
+
+	if len(carrier) == 0 {
+		return ctx
+	}
+
+	return propagation.TraceContext{}.Extract(ctx, carrier)
+}
@@ -0,0 +1,82 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package trace wires arana's optimizer and execution plans to
+// OpenTelemetry, behind a small pluggable provider so that tests and
+// embedders aren't forced to depend on a concrete exporter.
+package trace
+
+import (
+	"context"
+	"sync"
+)
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerProvider is the seam arana depends on instead of otel's
+// trace.TracerProvider directly, so that SetTracerProvider can be called at
+// startup while the rest of the codebase only ever starts spans through
+// StartRootSpan and StartSpan.
+type TracerProvider interface {
+	Tracer(name string) trace.Tracer
+}
+
+// Span re-exports otel's trace.Span so callers only need to import this
+// package.
+type Span = trace.Span
+
+const instrumentationName = "github.com/arana-db/arana/pkg/runtime"
+
+var (
+	_mu       sync.RWMutex
+	_provider TracerProvider = otel.GetTracerProvider()
+)
+
+// SetTracerProvider installs the TracerProvider used by arana's optimizer
+// and plans. Call it once during startup, before any query is optimized;
+// it is safe to call again in tests to swap in a recording provider.
+func SetTracerProvider(tp TracerProvider) {
+	_mu.Lock()
+	defer _mu.Unlock()
+	_provider = tp
+}
+
+// tracer returns the Tracer used to start arana's own spans.
+func tracer() trace.Tracer {
+	_mu.RLock()
+	tp := _provider
+	_mu.RUnlock()
+	return tp.Tracer(instrumentationName)
+}
+
+// StartRootSpan starts the root span for a logical SQL statement, e.g. when
+// the statement carries a TRACE() hint. The returned context carries the
+// span and should be threaded through the optimizer and plan execution so
+// that StartSpan below can parent child spans to it.
+func StartRootSpan(ctx context.Context, sql string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	opts = append([]trace.SpanStartOption{trace.WithAttributes(attrSQL(sql))}, opts...)
+	return tracer().Start(ctx, "arana.query", opts...)
+}
+
+// StartSpan starts a child span under whatever span (if any) ctx carries,
+// for a single downstream plan step, e.g. a per-shard execution.
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, opts...)
+}